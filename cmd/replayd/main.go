@@ -0,0 +1,53 @@
+// Command replayd re-serves a recorded match over the regular
+// proto.Game/Stream API so the existing frontend.View can act as a replay
+// viewer without any changes.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"github.com/nikit34/multiplayer_rpg_go/pkg/replay"
+	proto "github.com/nikit34/multiplayer_rpg_go/proto"
+)
+
+type replayServer struct {
+	proto.UnimplementedGameServer
+	player *replay.Player
+}
+
+func (s *replayServer) Stream(srv proto.Game_StreamServer) error {
+	log.Printf("serving replay %+v", s.player.Header())
+	return s.player.Play(srv, 0)
+}
+
+func main() {
+	addr := flag.String("addr", ":8888", "address to listen on")
+	path := flag.String("file", "", "path to a recorded journal file")
+	flag.Parse()
+
+	if *path == "" {
+		log.Fatal("missing required -file flag")
+	}
+
+	player, err := replay.Open(*path)
+	if err != nil {
+		log.Fatalf("unable to open replay %s: %v", *path, err)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("unable to listen on %s: %v", *addr, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	proto.RegisterGameServer(grpcServer, &replayServer{player: player})
+
+	log.Printf("replayd serving %s on %s", *path, *addr)
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatalf("serve error: %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package frontend
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,11 +13,61 @@ import (
 	"github.com/rivo/tview"
 )
 
+const maxLogLines = 50
+
 type View struct {
-	Game          *backend.Game
-	App           *tview.Application
-	CurrentPlayer uuid.UUID
-	Paused        bool
+	Game           *backend.Game
+	App            *tview.Application
+	CurrentPlayer  uuid.UUID
+	Paused         bool
+	Spectating     bool
+	FollowedPlayer uuid.UUID
+	log            *tview.TextView
+	logLines       []string
+}
+
+// AppendLogEvent adds a line to the scrollable event log pane, trimming the
+// oldest entries once maxLogLines is exceeded.
+func (view *View) AppendLogEvent(event string) {
+	view.logLines = append(view.logLines, event)
+	if len(view.logLines) > maxLogLines {
+		view.logLines = view.logLines[len(view.logLines)-maxLogLines:]
+	}
+	view.App.QueueUpdateDraw(func() {
+		view.log.SetText(strings.Join(view.logLines, "\n"))
+		view.log.ScrollToEnd()
+	})
+}
+
+// cycleFollowedPlayer advances FollowedPlayer to the next player entity, in a
+// stable order, wrapping back to the first. Called while spectating.
+func (view *View) cycleFollowedPlayer() {
+	view.Game.Mu.RLock()
+	defer view.Game.Mu.RUnlock()
+
+	players := []*backend.Player{}
+	for _, entity := range view.Game.Entities {
+		player, ok := entity.(*backend.Player)
+		if !ok {
+			continue
+		}
+		players = append(players, player)
+	}
+	if len(players) == 0 {
+		return
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID().String() < players[j].ID().String()
+	})
+
+	nextIndex := 0
+	for i, player := range players {
+		if player.ID() == view.FollowedPlayer {
+			nextIndex = (i + 1) % len(players)
+			break
+		}
+	}
+	view.FollowedPlayer = players[nextIndex].ID()
 }
 
 func NewView(game *backend.Game) *View {
@@ -30,6 +82,10 @@ func NewView(game *backend.Game) *View {
 	score := tview.NewTextView()
 	score.SetBorder(true).SetTitle("score")
 
+	log := tview.NewTextView()
+	log.SetBorder(true).SetTitle("log")
+	view.log = log
+
 	updateScore := func() {
 		text := ""
 		game.Mu.RLock()
@@ -52,9 +108,6 @@ func NewView(game *backend.Game) *View {
 	box := tview.NewBox().SetBorder(true).SetTitle("multiplayer-rpg")
 	box.SetDrawFunc(
 		func(screen tcell.Screen, x int, y int, width int, height int) (int, int, int, int) {
-			view.Game.Mu.RLock()
-			defer view.Game.Mu.RUnlock()
-
 			width = width - 1
 			height = height - 1
 			centerY := y + height/2
@@ -66,13 +119,27 @@ func NewView(game *backend.Game) *View {
 				}
 			}
 			screen.SetContent(centerX, centerY, 'O', nil, tcell.StyleDefault.Foreground(tcell.ColorWhite))
+
 			view.Game.Mu.RLock()
+
+			origin := backend.Coordinate{}
+			if view.Spectating {
+				for _, entity := range view.Game.Entities {
+					player, ok := entity.(*backend.Player)
+					if ok && player.ID() == view.FollowedPlayer {
+						origin = player.Position()
+						break
+					}
+				}
+			}
+
 			for _, entity := range view.Game.Entities {
 				positioner, ok := entity.(backend.Positioner)
 				if !ok {
 					continue
 				}
 				position := positioner.Position()
+				position = backend.Coordinate{X: position.X - origin.X, Y: position.Y - origin.Y}
 
 				var icon rune
 				var color tcell.Color
@@ -106,6 +173,13 @@ func NewView(game *backend.Game) *View {
 			return e
 		}
 
+		if view.Spectating {
+			if e.Key() == tcell.KeyTab {
+				view.cycleFollowedPlayer()
+			}
+			return e
+		}
+
 		direction := backend.DirectionStop
 		switch e.Key() {
 		case tcell.KeyUp:
@@ -147,13 +221,18 @@ func NewView(game *backend.Game) *View {
 
 	pages.AddPage("viewport", box, true, true)
 	pages.AddPage("score", score, true, false)
+	pages.AddPage("log", log, true, false)
 	app.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
 		if e.Rune() == 'p' {
 			updateScore()
 			pages.ShowPage("score")
 		}
+		if e.Rune() == 'l' {
+			pages.ShowPage("log")
+		}
 		if e.Key() == tcell.KeyESC {
 			pages.HidePage("score")
+			pages.HidePage("log")
 		}
 		return e
 	})
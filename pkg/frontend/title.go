@@ -0,0 +1,101 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rivo/tview"
+
+	proto "github.com/nikit34/multiplayer_rpg_go/proto"
+)
+
+// TitleView is the pages shown before a game is joined: a browser listing
+// open matches fetched over the lobby RPC, and a form for creating a new
+// one. Selecting a game or submitting the form calls back with the chosen
+// match ID so the caller can dial Game.Stream with it.
+type TitleView struct {
+	App    *tview.Application
+	Pages  *tview.Pages
+	list   *tview.List
+	client proto.LobbyClient
+}
+
+func NewTitleView(app *tview.Application, client proto.LobbyClient, onSelected func(matchID string)) *TitleView {
+	pages := tview.NewPages()
+
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("games")
+
+	view := &TitleView{
+		App:    app,
+		Pages:  pages,
+		list:   list,
+		client: client,
+	}
+
+	form := tview.NewForm()
+	name := "new game"
+	mapSize := "64"
+	maxPlayers := "8"
+	roundLength := "180"
+	private := false
+	password := ""
+
+	form.AddInputField("name", name, 20, nil, func(text string) { name = text })
+	form.AddInputField("map size", mapSize, 6, nil, func(text string) { mapSize = text })
+	form.AddInputField("max players", maxPlayers, 6, nil, func(text string) { maxPlayers = text })
+	form.AddInputField("round length (s)", roundLength, 6, nil, func(text string) { roundLength = text })
+	form.AddCheckbox("private", private, func(checked bool) { private = checked })
+	form.AddInputField("password", password, 20, nil, func(text string) { password = text })
+	form.AddButton("create", func() {
+		resp, err := view.client.CreateGame(context.Background(), &proto.CreateGameRequest{
+			Name:               name,
+			MapSize:            atoi32(mapSize),
+			MaxPlayers:         atoi32(maxPlayers),
+			RoundLengthSeconds: atoi32(roundLength),
+			Private:            private,
+			Password:           password,
+		})
+		if err != nil {
+			return
+		}
+		onSelected(resp.Id)
+	})
+	form.SetBorder(true).SetTitle("create game")
+
+	view.refresh()
+
+	list.SetSelectedFunc(func(index int, gameID string, secondaryText string, shortcut rune) {
+		onSelected(gameID)
+	})
+
+	flex := tview.NewFlex()
+	flex.AddItem(list, 0, 1, true)
+	flex.AddItem(form, 0, 1, false)
+
+	pages.AddPage("title", flex, true, true)
+
+	return view
+}
+
+func (view *TitleView) refresh() {
+	view.list.Clear()
+	resp, err := view.client.ListGames(context.Background(), &proto.ListGamesRequest{})
+	if err != nil {
+		return
+	}
+	for _, game := range resp.Games {
+		view.list.AddItem(
+			game.Id,
+			fmt.Sprintf("%s (%d/%d players)", game.Name, game.PlayerCount, game.MaxPlayers),
+			0,
+			nil,
+		)
+	}
+}
+
+func atoi32(text string) int32 {
+	var value int32
+	fmt.Sscanf(text, "%d", &value)
+	return value
+}
@@ -0,0 +1,249 @@
+package lobby
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nikit34/multiplayer_rpg_go/pkg/backend"
+	"github.com/nikit34/multiplayer_rpg_go/pkg/replay"
+	"github.com/nikit34/multiplayer_rpg_go/pkg/server"
+	proto "github.com/nikit34/multiplayer_rpg_go/proto"
+)
+
+// replayDir is where match journals are written, one file per match named
+// by its ID. Every round the match plays goes into that same file; journals
+// are not split per round.
+const replayDir = "replays"
+
+// emptyMatchGrace is how long a match may sit with no connected players or
+// spectators before the reaper tears it down, freeing its GameServer's
+// background goroutines and closing its replay journal.
+const emptyMatchGrace = 2 * time.Minute
+
+// reapInterval controls how often the reaper scans for empty matches.
+const reapInterval = 30 * time.Second
+
+// match bundles everything a single running game needs: its own backend
+// simulation, the GameServer fronting it, and the lobby-level configuration
+// used to list and join it.
+type match struct {
+	ID          uuid.UUID
+	Name        string
+	Server      *server.GameServer
+	MapSize     int
+	MaxPlayers  int
+	RoundLength time.Duration
+	Private     bool
+	Password    string
+	Recorder    *replay.Recorder
+	// EmptySince is when the reaper last observed this match with no
+	// connected players or spectators, zero while it has at least one.
+	EmptySince time.Time
+}
+
+// LobbyServer implements proto.LobbyServer, tracking every in-progress match
+// and dispatching incoming Game streams to the right one.
+type LobbyServer struct {
+	proto.UnimplementedLobbyServer
+	matches map[uuid.UUID]*match
+	mu      sync.RWMutex
+}
+
+func NewLobbyServer() *LobbyServer {
+	l := &LobbyServer{
+		matches: make(map[uuid.UUID]*match),
+	}
+	go l.reapEmptyMatches()
+	return l
+}
+
+// reapEmptyMatches periodically tears down matches that have sat with no
+// connected players or spectators for longer than emptyMatchGrace, stopping
+// their GameServer's background goroutines and closing their replay
+// journal so neither leaks for the life of the process. The grace period is
+// measured from when a match was last observed empty, not from when it was
+// created, so a long-running match that only briefly empties (e.g. during a
+// round transition) survives the next scan instead of being torn down.
+func (l *LobbyServer) reapEmptyMatches() {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+
+		l.mu.Lock()
+		for id, m := range l.matches {
+			if m.Server.PlayerCount() > 0 || m.Server.SpectatorCount() > 0 {
+				m.EmptySince = time.Time{}
+				continue
+			}
+			if m.EmptySince.IsZero() {
+				m.EmptySince = now
+				continue
+			}
+			if now.Sub(m.EmptySince) < emptyMatchGrace {
+				continue
+			}
+			log.Printf("lobby: reaping empty match %s (%s)", id, m.Name)
+			delete(l.matches, id)
+			m.Server.Stop()
+			if m.Recorder != nil {
+				if err := m.Recorder.Close(); err != nil {
+					log.Printf("replay: error closing recorder for match %s: %v", id, err)
+				}
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *LobbyServer) ListGames(ctx context.Context, req *proto.ListGamesRequest) (*proto.ListGamesResponse, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	games := []*proto.GameSummary{}
+	for _, m := range l.matches {
+		if m.Private {
+			continue
+		}
+		games = append(games, &proto.GameSummary{
+			Id:          m.ID.String(),
+			Name:        m.Name,
+			MapSize:     int32(m.MapSize),
+			MaxPlayers:  int32(m.MaxPlayers),
+			PlayerCount: m.Server.PlayerCount(),
+			Private:     m.Private,
+		})
+	}
+
+	return &proto.ListGamesResponse{Games: games}, nil
+}
+
+func (l *LobbyServer) CreateGame(ctx context.Context, req *proto.CreateGameRequest) (*proto.CreateGameResponse, error) {
+	roundLength := time.Duration(req.RoundLengthSeconds) * time.Second
+	mapSize := int(req.MapSize)
+	seed := rand.Int63()
+
+	// newGame builds and starts a fresh simulation. It's shared between the
+	// match's initial game and ResetGame's replacement so a hard reset
+	// starts the new backend.Game's tick loop exactly as the match's
+	// creation did, instead of leaving it unstarted.
+	newGame := func() *backend.Game {
+		game := backend.NewGame(mapSize)
+		go game.HandleActions()
+		return game
+	}
+
+	game := newGame()
+	gameServer := server.NewGameServer(game)
+	gameServer.SetGameFactory(newGame)
+	gameServer.SetMaxPlayers(int(req.MaxPlayers))
+
+	m := &match{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Server:      gameServer,
+		MapSize:     mapSize,
+		MaxPlayers:  int(req.MaxPlayers),
+		RoundLength: roundLength,
+		Private:     req.Private,
+		Password:    req.Password,
+	}
+
+	if recorder, err := newMatchRecorder(m, seed, game); err != nil {
+		log.Printf("replay: not recording match %s: %v", m.ID, err)
+	} else {
+		gameServer.SetRecorder(recorder)
+		m.Recorder = recorder
+	}
+
+	l.mu.Lock()
+	l.matches[m.ID] = m
+	l.mu.Unlock()
+
+	return &proto.CreateGameResponse{Id: m.ID.String()}, nil
+}
+
+func newMatchRecorder(m *match, seed int64, game *backend.Game) (*replay.Recorder, error) {
+	if err := os.MkdirAll(replayDir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(replayDir, fmt.Sprintf("%s.replay", m.ID))
+	header := &proto.ReplayHeader{
+		Seed:               seed,
+		MapSize:            int32(m.MapSize),
+		RoundLengthSeconds: int32(m.RoundLength.Seconds()),
+	}
+
+	return replay.NewRecorder(path, game, header)
+}
+
+func (l *LobbyServer) JoinGame(ctx context.Context, req *proto.JoinGameRequest) (*proto.JoinGameResponse, error) {
+	matchID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := l.find(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Private && m.Password != req.Password {
+		return nil, errors.New("invalid password")
+	}
+
+	if int(m.Server.PlayerCount()) >= m.MaxPlayers {
+		return nil, errors.New("match is full")
+	}
+
+	return &proto.JoinGameResponse{Id: m.ID.String()}, nil
+}
+
+func (l *LobbyServer) find(matchID uuid.UUID) (*match, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	m, ok := l.matches[matchID]
+	if !ok {
+		return nil, errors.New("no such game")
+	}
+	return m, nil
+}
+
+// Stream implements proto.GameServer by peeking the first message of a new
+// connection for its MatchId and handing the rest of the stream off to that
+// match's own GameServer.
+func (l *LobbyServer) Stream(srv proto.Game_StreamServer) error {
+	req, err := srv.Recv()
+	if err != nil {
+		return err
+	}
+
+	connect := req.GetConnect()
+	if connect == nil {
+		return errors.New("expected connect message")
+	}
+
+	matchID, err := uuid.Parse(connect.MatchId)
+	if err != nil {
+		return err
+	}
+
+	m, err := l.find(matchID)
+	if err != nil {
+		return err
+	}
+
+	return m.Server.StreamFrom(req, srv)
+}
@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"time"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	proto "github.com/nikit34/multiplayer_rpg_go/proto"
+)
+
+// keyframeOffset records where a keyframe begins in the journal file, so
+// Play can seek near a requested point without rescanning from byte zero.
+type keyframeOffset struct {
+	offset     time.Duration
+	fileOffset int64
+}
+
+// Player reads a journal written by a Recorder and re-serves it over
+// proto.Game/Stream.
+type Player struct {
+	path      string
+	header    *proto.ReplayHeader
+	headerLen int64
+	keyframes []keyframeOffset
+}
+
+// Open reads the journal's header and indexes its keyframes.
+func Open(path string) (*Player, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	_, headerData, headerLen, err := readRecord(reader)
+	if err != nil {
+		return nil, err
+	}
+	header := &proto.ReplayHeader{}
+	if err := protobuf.Unmarshal(headerData, header); err != nil {
+		return nil, err
+	}
+
+	p := &Player{path: path, header: header, headerLen: headerLen}
+
+	var fileOffset int64
+	for {
+		offset, data, recordLen, err := readRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp := &proto.Response{}
+		if err := protobuf.Unmarshal(data, resp); err != nil {
+			return nil, err
+		}
+		if _, ok := resp.Action.(*proto.Response_Initialize); ok {
+			p.keyframes = append(p.keyframes, keyframeOffset{offset: offset, fileOffset: fileOffset})
+		}
+
+		fileOffset += recordLen
+	}
+
+	return p, nil
+}
+
+func (p *Player) Header() *proto.ReplayHeader {
+	return p.header
+}
+
+// Play streams the journal to srv, pacing frames on a wall-clock schedule
+// starting from the latest keyframe at or before seekTo (or from the first
+// frame when there is none). Client input is received and discarded: a
+// recording has no player to steer it.
+func (p *Player) Play(srv proto.Game_StreamServer, seekTo time.Duration) error {
+	go discardRecv(srv)
+
+	file, err := os.Open(p.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	startFileOffset, startOffset := p.nearestKeyframe(seekTo)
+
+	if _, err := file.Seek(p.headerLen+startFileOffset, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(file)
+
+	playbackStarted := time.Now()
+	for {
+		offset, data, _, err := readRecord(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp := &proto.Response{}
+		if err := protobuf.Unmarshal(data, resp); err != nil {
+			return err
+		}
+
+		wait := (offset - startOffset) - time.Since(playbackStarted)
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+
+		if err := srv.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// nearestKeyframe returns the in-file byte offset (relative to just after
+// the header record) and recorded timestamp of the latest keyframe at or
+// before seekTo, so playback can jump ahead without replaying from zero.
+func (p *Player) nearestKeyframe(seekTo time.Duration) (int64, time.Duration) {
+	var best keyframeOffset
+	for _, kf := range p.keyframes {
+		if kf.offset > seekTo {
+			break
+		}
+		best = kf
+	}
+	return best.fileOffset, best.offset
+}
+
+func discardRecv(srv proto.Game_StreamServer) {
+	for {
+		if _, err := srv.Recv(); err != nil {
+			return
+		}
+	}
+}
+
+// readRecord reads one [offsetMillis int64][length uint32][payload] record
+// and returns the raw payload bytes alongside the record's total on-disk
+// size so callers can track file position without a separate Seek.
+func readRecord(reader *bufio.Reader) (time.Duration, []byte, int64, error) {
+	var offsetMillis int64
+	if err := binary.Read(reader, binary.BigEndian, &offsetMillis); err != nil {
+		return 0, nil, 0, err
+	}
+
+	var length uint32
+	if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+		return 0, nil, 0, err
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return 0, nil, 0, err
+	}
+
+	recordLen := int64(8+4) + int64(length)
+	offset := time.Duration(offsetMillis) * time.Millisecond
+	return offset, data, recordLen, nil
+}
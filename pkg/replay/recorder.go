@@ -0,0 +1,149 @@
+// Package replay records a running match to disk as a length-prefixed
+// protobuf journal and plays such a journal back over the unmodified
+// proto.Game/Stream API.
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	protobuf "github.com/golang/protobuf/proto"
+
+	"github.com/nikit34/multiplayer_rpg_go/pkg/backend"
+	proto "github.com/nikit34/multiplayer_rpg_go/proto"
+)
+
+// keyframeInterval controls how often a full entity-list snapshot is
+// written to the journal so a Player can seek without replaying from the
+// very start.
+const keyframeInterval = 10 * time.Second
+
+// Recorder writes every broadcast response for a match to a journal file:
+// a header frame with the match's seed and configuration, followed by one
+// frame per broadcast, interspersed with periodic keyframes.
+type Recorder struct {
+	mu           sync.Mutex
+	file         *os.File
+	writer       *bufio.Writer
+	game         *backend.Game
+	startedAt    time.Time
+	lastKeyframe time.Time
+}
+
+// NewRecorder creates path and writes the journal header. The caller is
+// responsible for calling Close once it stops recording; until then every
+// recorded round is appended to this one file.
+func NewRecorder(path string, game *backend.Game, header *proto.ReplayHeader) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{
+		file:      file,
+		writer:    bufio.NewWriter(file),
+		game:      game,
+		startedAt: time.Now(),
+	}
+
+	if err := r.writeFrame(0, header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := r.writer.Flush(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.lastKeyframe = r.startedAt
+
+	return r, nil
+}
+
+// Record appends a broadcast response to the journal, tagging it with its
+// offset from the start of the recording.
+func (r *Recorder) Record(resp *proto.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offset := time.Since(r.startedAt)
+
+	if time.Since(r.lastKeyframe) >= keyframeInterval {
+		if r.writeKeyframeLocked(offset) {
+			r.lastKeyframe = time.Now()
+		}
+	}
+
+	if err := r.writeFrame(offset, resp); err != nil {
+		log.Printf("replay: failed to write frame: %v", err)
+		return
+	}
+	// Flush after every frame rather than waiting for Close, so a match
+	// that is still running (or crashes before teardown) still leaves a
+	// playable journal on disk instead of whatever bufio happened to hold.
+	if err := r.writer.Flush(); err != nil {
+		log.Printf("replay: failed to flush frame: %v", err)
+	}
+}
+
+// writeKeyframeLocked snapshots the live game's entities into a keyframe.
+// Record is called synchronously from GameServer.broadcast, which some
+// callers (e.g. removePlayer) invoke while already holding game.Mu for
+// writing, and others (handleRoundOverChange) while holding it for
+// reading with a writer possibly already queued behind them. A blocking
+// RLock here would then deadlock against the same goroutine, so this
+// uses TryRLock and simply skips the keyframe when the game is already
+// locked; Record retries on the next broadcast.
+func (r *Recorder) writeKeyframeLocked(offset time.Duration) bool {
+	if !r.game.Mu.TryRLock() {
+		return false
+	}
+	entities := make([]*proto.Entity, 0, len(r.game.Entities))
+	for _, entity := range r.game.Entities {
+		if protoEntity := proto.GetProtoEntity(entity); protoEntity != nil {
+			entities = append(entities, protoEntity)
+		}
+	}
+	r.game.Mu.RUnlock()
+
+	keyframe := &proto.Response{
+		Action: &proto.Response_Initialize{
+			Initialize: &proto.Initialize{Entities: entities},
+		},
+	}
+	if err := r.writeFrame(offset, keyframe); err != nil {
+		log.Printf("replay: failed to write keyframe: %v", err)
+	}
+	return true
+}
+
+// writeFrame writes [offsetMillis int64][length uint32][marshaled message].
+func (r *Recorder) writeFrame(offset time.Duration, msg protobuf.Message) error {
+	data, err := protobuf.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := binary.Write(r.writer, binary.BigEndian, offset.Milliseconds()); err != nil {
+		return err
+	}
+	if err := binary.Write(r.writer, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err = r.writer.Write(data)
+	return err
+}
+
+// Close flushes and closes the journal file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.writer.Flush(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
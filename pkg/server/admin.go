@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/google/uuid"
+
+	"github.com/nikit34/multiplayer_rpg_go/pkg/backend"
+	proto "github.com/nikit34/multiplayer_rpg_go/proto"
+)
+
+const adminTokenMetadataKey = "x-admin-token"
+
+var adminMethods = map[string]bool{
+	"/proto.Game/ResetRound": true,
+	"/proto.Game/ResetGame":  true,
+}
+
+// AuthAdminInterceptor builds a grpc.UnaryServerInterceptor that rejects
+// calls to the admin RPCs (ResetRound, ResetGame) unless the caller presents
+// the configured shared-secret token in the "x-admin-token" metadata key.
+// Non-admin methods pass through untouched.
+func AuthAdminInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !adminMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing metadata")
+		}
+
+		values := md.Get(adminTokenMetadataKey)
+		if len(values) == 0 || values[0] != token {
+			return nil, status.Error(codes.PermissionDenied, "invalid admin token")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// SetGameFactory registers how to build a fresh backend.Game for this match,
+// used by ResetGame to tear down and recreate the simulation with the same
+// configuration it was originally created with.
+func (s *GameServer) SetGameFactory(factory func() *backend.Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gameFactory = factory
+}
+
+// ResetRound clears lasers, respawns players at the origin, and zeroes
+// scores, but leaves every client connected.
+func (s *GameServer) ResetRound(ctx context.Context, req *proto.ResetRoundRequest) (*proto.ResetRoundResponse, error) {
+	game := s.currentGame()
+
+	game.Mu.Lock()
+	laserIDs := []uuid.UUID{}
+	players := []*backend.Player{}
+	for _, entity := range game.Entities {
+		switch e := entity.(type) {
+		case *backend.Laser:
+			laserIDs = append(laserIDs, e.ID())
+		case *backend.Player:
+			e.CurrentPosition = backend.Coordinate{X: 0, Y: 0}
+			players = append(players, e)
+		}
+	}
+	for id := range game.Score {
+		game.Score[id] = 0
+	}
+	game.Mu.Unlock()
+
+	for _, id := range laserIDs {
+		game.Mu.Lock()
+		game.RemoveEntity(id)
+		game.Mu.Unlock()
+	}
+
+	protoPlayers := make([]*proto.Player, 0, len(players))
+	for _, player := range players {
+		protoPlayers = append(protoPlayers, proto.GetProtoPlayer(player))
+	}
+
+	s.broadcast(&proto.Response{
+		Action: &proto.Response_RoundStart{
+			RoundStart: &proto.RoundStart{
+				Players:        protoPlayers,
+				SpectatorCount: s.spectatorCount(),
+			},
+		},
+	})
+
+	return &proto.ResetRoundResponse{}, nil
+}
+
+// ResetGame tears down the current backend.Game, recreates it from the
+// registered factory, and forces every connected client to re-initialize.
+func (s *GameServer) ResetGame(ctx context.Context, req *proto.ResetGameRequest) (*proto.ResetGameResponse, error) {
+	s.mu.Lock()
+	factory := s.gameFactory
+	s.mu.Unlock()
+	if factory == nil {
+		return nil, errors.New("game factory not configured, cannot reset")
+	}
+
+	s.mu.Lock()
+	if s.gameStopCh != nil {
+		close(s.gameStopCh)
+	}
+	s.game = factory()
+	s.mu.Unlock()
+
+	s.WatchChanges()
+	s.flushClientState()
+
+	s.broadcast(&proto.Response{
+		Action: &proto.Response_Reinitialize{
+			Reinitialize: &proto.Reinitialize{},
+		},
+	})
+
+	return &proto.ResetGameResponse{}, nil
+}
+
+// flushClientState drops every client's buffered input and restarts each
+// connected player's idle timer, without disconnecting anyone.
+func (s *GameServer) flushClientState() {
+	s.inputMu.Lock()
+	s.inputQueues = make(map[uuid.UUID][]queuedAction)
+	s.inputMu.Unlock()
+
+	now := time.Now()
+	s.mu.Lock()
+	for _, currentClient := range s.clients {
+		currentClient.LastActivity = now
+	}
+	s.mu.Unlock()
+}
@@ -14,24 +14,92 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/nikit34/multiplayer_rpg_go/pkg/backend"
+	"github.com/nikit34/multiplayer_rpg_go/pkg/replay"
 	proto "github.com/nikit34/multiplayer_rpg_go/proto"
 )
 
+const (
+	idleTimeout       = 2 * time.Minute
+	idleCheckInterval = 10 * time.Second
+
+	// inputBufferWindow is how long an input is held before being applied,
+	// giving clients with higher RTT a chance to have their inputs for the
+	// same tick arrive and be ordered fairly.
+	inputBufferWindow = 150 * time.Millisecond
+	inputTickInterval = 20 * time.Millisecond
+	maxClientQueueLen = 64
+	pongInterval      = 2 * time.Second
+)
+
 type client struct {
 	StreamServer proto.Game_StreamServer
-	Cancel context.CancelFunc
-	ID uuid.UUID
+	Cancel       context.CancelFunc
+	ID           uuid.UUID
+	Name         string
+	LastActivity time.Time
+	RTTOffset    time.Duration
+	Role         proto.Role
+}
+
+// queuedAction is a buffered move/laser input awaiting its deadline. Queues
+// are per-client slices appended in arrival order so inputs from the same
+// client are never reordered relative to one another.
+type queuedAction struct {
+	seq      uint64
+	deadline time.Time
+	action   interface{}
 }
 
 type GameServer struct {
 	proto.UnimplementedGameServer
-	game    *backend.Game
-	clients map[uuid.UUID]*client
-	mu      sync.RWMutex
+	game        *backend.Game
+	clients     map[uuid.UUID]*client
+	spectators  map[uuid.UUID]*client
+	mu          sync.RWMutex
+	inputQueues map[uuid.UUID][]queuedAction
+	inputMu     sync.Mutex
+	gameFactory func() *backend.Game
+	gameStopCh  chan struct{}
+	recorder    *replay.Recorder
+	stopCh      chan struct{}
+	maxPlayers  int
+}
+
+// SetMaxPlayers caps how many non-spectator clients this match will admit;
+// connects past the cap are rejected where the client is actually added to
+// s.clients. Zero (the default) means no cap.
+func (s *GameServer) SetMaxPlayers(maxPlayers int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPlayers = maxPlayers
+}
+
+// SetRecorder attaches a replay.Recorder so every broadcast is journaled to
+// disk alongside being sent to clients. Pass nil to stop recording.
+func (s *GameServer) SetRecorder(recorder *replay.Recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = recorder
+}
+
+// currentGame returns the active backend.Game under s.mu, so a reader can
+// never observe a half-swapped pointer while ResetGame is reassigning it.
+func (s *GameServer) currentGame() *backend.Game {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.game
 }
 
 func (s *GameServer) broadcast(resp *proto.Response) {
+	s.mu.RLock()
+	recorder := s.recorder
+	s.mu.RUnlock()
+	if recorder != nil {
+		recorder.Record(resp)
+	}
+
 	removals := []uuid.UUID{}
+	spectatorRemovals := []*client{}
 
 	s.mu.RLock()
 	for id, currentClient := range s.clients {
@@ -42,11 +110,23 @@ func (s *GameServer) broadcast(resp *proto.Response) {
 		}
 		log.Printf("broadcasted %+v message to %s", resp, id)
 	}
+	for id, currentClient := range s.spectators {
+		if err := currentClient.StreamServer.Send(resp); err != nil {
+			log.Printf("broadcast error %v, removing spectator %s", err, id)
+			spectatorRemovals = append(spectatorRemovals, currentClient)
+		}
+		log.Printf("broadcasted %+v message to spectator %s", resp, id)
+	}
 	s.mu.RUnlock()
 
 	for _, id := range removals {
 		s.removePlayer(id)
 	}
+	for _, currentClient := range spectatorRemovals {
+		s.mu.Lock()
+		s.removeSpectator(currentClient)
+		s.mu.Unlock()
+	}
 }
 
 func (s *GameServer) handleMoveChange(change backend.MoveChange) {
@@ -95,17 +175,53 @@ func (s *GameServer) handlePlayerRespawnChange(change backend.PlayerRespawnChang
 }
 
 func (s *GameServer) handleRoundOverChange(change backend.RoundOverChange) {
-	s.game.Mu.RLock()
-	defer s.game.Mu.RUnlock()
-	timestamp, err := ptypes.TimestampProto(s.game.NewRoundAt)
+	game := s.currentGame()
+	game.Mu.RLock()
+	defer game.Mu.RUnlock()
+	timestamp, err := ptypes.TimestampProto(game.NewRoundAt)
 	if err != nil {
-		log.Fatalf("unable to parse new round timestamp %v", s.game.NewRoundAt)
+		log.Fatalf("unable to parse new round timestamp %v", game.NewRoundAt)
 	}
 	resp := proto.Response{
 		Action: &proto.Response_RoundOver{
 			RoundOver: &proto.RoundOver{
-				RoundWinnerId: s.game.RoundWinner.String(),
-				NewRoundAt:    timestamp,
+				RoundWinnerId:  game.RoundWinner.String(),
+				NewRoundAt:     timestamp,
+				SpectatorCount: s.spectatorCount(),
+			},
+		},
+	}
+	s.broadcast(&resp)
+}
+
+func (s *GameServer) spectatorCount() int32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int32(len(s.spectators))
+}
+
+// PlayerCount returns the number of currently connected (non-spectator)
+// clients, for a lobby to surface in its game browser and enforce a
+// match's MaxPlayers limit.
+func (s *GameServer) PlayerCount() int32 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return int32(len(s.clients))
+}
+
+// SpectatorCount exposes spectatorCount to callers outside this package,
+// such as a lobby deciding whether a match is empty enough to tear down.
+func (s *GameServer) SpectatorCount() int32 {
+	return s.spectatorCount()
+}
+
+func (s *GameServer) broadcastLobbyEvent(eventType proto.LobbyEvent_Type, playerID uuid.UUID, playerName string) {
+	resp := proto.Response{
+		Action: &proto.Response_Lobby{
+			Lobby: &proto.LobbyEvent{
+				Type:       eventType,
+				PlayerId:   playerID.String(),
+				PlayerName: playerName,
 			},
 		},
 	}
@@ -113,43 +229,58 @@ func (s *GameServer) handleRoundOverChange(change backend.RoundOverChange) {
 }
 
 func (s *GameServer) handleRoundStartChange(change backend.RoundStartChange) {
+	game := s.currentGame()
 	players := []*proto.Player{}
-	s.game.Mu.RLock()
-	for _, entity := range s.game.Entities {
+	game.Mu.RLock()
+	for _, entity := range game.Entities {
 		player, ok := entity.(*backend.Player)
 		if !ok {
 			continue
 		}
 		players = append(players, proto.GetProtoPlayer(player))
 	}
-	s.game.Mu.RUnlock()
+	game.Mu.RUnlock()
 	resp := proto.Response{
 		Action: &proto.Response_RoundStart{
 			RoundStart: &proto.RoundStart{
-				Players: players,
+				Players:        players,
+				SpectatorCount: s.spectatorCount(),
 			},
 		},
 	}
 	s.broadcast(&resp)
 }
 
+// WatchChanges starts the goroutine that translates backend.Game change
+// events into broadcasts. It binds to whichever *backend.Game is current at
+// call time and can be cleanly stopped (e.g. by ResetGame) via gameStopCh.
 func (s *GameServer) WatchChanges() {
+	s.mu.Lock()
+	game := s.game
+	stop := make(chan struct{})
+	s.gameStopCh = stop
+	s.mu.Unlock()
+
 	go func() {
 		for {
-			change := <-s.game.ChangeChannel
-			switch change_type := change.(type) {
-			case backend.MoveChange:
-				s.handleMoveChange(change_type)
-			case backend.AddEntityChange:
-				s.handleAddEntityChange(change_type)
-			case backend.RemoveEntityChange:
-				s.handleRemoveEntityChange(change_type)
-			case backend.PlayerRespawnChange:
-				s.handlePlayerRespawnChange(change_type)
-			case backend.RoundOverChange:
-				s.handleRoundOverChange(change_type)
-			case backend.RoundStartChange:
-				s.handleRoundStartChange(change_type)
+			select {
+			case <-stop:
+				return
+			case change := <-game.ChangeChannel:
+				switch change_type := change.(type) {
+				case backend.MoveChange:
+					s.handleMoveChange(change_type)
+				case backend.AddEntityChange:
+					s.handleAddEntityChange(change_type)
+				case backend.RemoveEntityChange:
+					s.handleRemoveEntityChange(change_type)
+				case backend.PlayerRespawnChange:
+					s.handlePlayerRespawnChange(change_type)
+				case backend.RoundOverChange:
+					s.handleRoundOverChange(change_type)
+				case backend.RoundStartChange:
+					s.handleRoundStartChange(change_type)
+				}
 			}
 		}
 	}()
@@ -157,23 +288,201 @@ func (s *GameServer) WatchChanges() {
 
 func NewGameServer(game *backend.Game) *GameServer {
 	server := &GameServer{
-		game:    game,
-		clients: make(map[uuid.UUID]*client),
+		game:        game,
+		clients:     make(map[uuid.UUID]*client),
+		spectators:  make(map[uuid.UUID]*client),
+		inputQueues: make(map[uuid.UUID][]queuedAction),
+		stopCh:      make(chan struct{}),
 	}
 	server.WatchChanges()
+	go server.watchIdleClients()
+	go server.drainInputQueues()
+	go server.sendPongs()
 	return server
 }
 
+// Stop permanently ends this match's background goroutines (the change
+// watcher, idle watchdog, input drain, and pong broadcast). Call it once a
+// match has been torn down and will never be streamed to or reset again.
+func (s *GameServer) Stop() {
+	s.mu.Lock()
+	if s.gameStopCh != nil {
+		close(s.gameStopCh)
+		s.gameStopCh = nil
+	}
+	s.mu.Unlock()
+	close(s.stopCh)
+}
+
+// bufferAction enqueues a timestamped, sequence-numbered input for later
+// application instead of forwarding it to the game straight away, so that
+// inputs from clients with different RTTs can be applied in a fair order.
+// Inputs that are already past their deadline by the time they arrive are
+// applied immediately and logged as late.
+func (s *GameServer) bufferAction(clientID uuid.UUID, seq uint64, sentAt time.Time, action interface{}) {
+	s.mu.RLock()
+	offset := time.Duration(0)
+	if currentClient, ok := s.clients[clientID]; ok {
+		offset = currentClient.RTTOffset
+	}
+	s.mu.RUnlock()
+
+	deadline := sentAt.Add(inputBufferWindow + offset)
+
+	s.inputMu.Lock()
+	defer s.inputMu.Unlock()
+
+	queue := s.inputQueues[clientID]
+	if !deadline.After(time.Now()) {
+		// This input is already late, but earlier inputs from the same
+		// client may still be sitting in the queue. Append it and flush
+		// the queue up through this entry rather than forwarding it out
+		// of band, so it can never jump ahead of them.
+		log.Printf("late input from %s (seq %d), flushing queue", clientID, seq)
+		queue = append(queue, queuedAction{seq: seq, deadline: deadline, action: action})
+		for _, qa := range queue {
+			s.forwardAction(qa.action)
+		}
+		delete(s.inputQueues, clientID)
+		return
+	}
+
+	if len(queue) >= maxClientQueueLen {
+		log.Printf("dropping input from %s: queue full", clientID)
+		return
+	}
+	s.inputQueues[clientID] = append(queue, queuedAction{seq: seq, deadline: deadline, action: action})
+}
+
+func (s *GameServer) forwardAction(action interface{}) {
+	game := s.currentGame()
+	switch a := action.(type) {
+	case backend.MoveAction:
+		game.ActionChannel <- a
+	case backend.LaserAction:
+		game.ActionChannel <- a
+	}
+}
+
+// drainInputQueues runs on a fixed tick, applying any buffered inputs whose
+// deadline has passed. Because each client's queue is append-ordered, only
+// the front of the queue is ever inspected, guaranteeing a client's own
+// inputs are applied in the order they were sent.
+func (s *GameServer) drainInputQueues() {
+	ticker := time.NewTicker(inputTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.inputMu.Lock()
+			for clientID, queue := range s.inputQueues {
+				i := 0
+				for i < len(queue) && !queue[i].deadline.After(now) {
+					i++
+				}
+				if i == 0 {
+					continue
+				}
+				for _, qa := range queue[:i] {
+					s.forwardAction(qa.action)
+				}
+				s.inputQueues[clientID] = append([]queuedAction{}, queue[i:]...)
+			}
+			s.inputMu.Unlock()
+		}
+	}
+}
+
+// sendPongs periodically broadcasts the server's wall-clock time so clients
+// can estimate clock skew and RTT for lag compensation.
+func (s *GameServer) sendPongs() {
+	ticker := time.NewTicker(pongInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			serverTime, err := ptypes.TimestampProto(time.Now())
+			if err != nil {
+				log.Printf("unable to build pong timestamp: %v", err)
+				continue
+			}
+			s.broadcast(&proto.Response{
+				Action: &proto.Response_Pong{
+					Pong: &proto.Pong{ServerTime: serverTime},
+				},
+			})
+		}
+	}
+}
+
+// watchIdleClients periodically scans connected clients for inactivity and
+// disconnects anyone who hasn't sent a move/laser/chat action within
+// idleTimeout, freeing up their slot without waiting on a transport error.
+func (s *GameServer) watchIdleClients() {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			idle := []*client{}
+
+			s.mu.RLock()
+			for _, currentClient := range s.clients {
+				if now.Sub(currentClient.LastActivity) > idleTimeout {
+					idle = append(idle, currentClient)
+				}
+			}
+			s.mu.RUnlock()
+
+			for _, currentClient := range idle {
+				log.Printf("kicking idle client %s", currentClient.ID)
+				s.mu.Lock()
+				s.removeClient(currentClient)
+				s.mu.Unlock()
+				s.removePlayer(currentClient.ID)
+				s.broadcastLobbyEvent(proto.LobbyEvent_PLAYER_IDLE_KICKED, currentClient.ID, currentClient.Name)
+			}
+		}
+	}
+}
+
+func (s *GameServer) touchActivity(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if currentClient, ok := s.clients[id]; ok {
+		currentClient.LastActivity = time.Now()
+	}
+}
+
 func (s *GameServer) removeClient(currentClient *client) {
 	delete(s.clients, currentClient.ID)
 	currentClient.Cancel()
+
+	s.inputMu.Lock()
+	delete(s.inputQueues, currentClient.ID)
+	s.inputMu.Unlock()
+}
+
+func (s *GameServer) removeSpectator(currentClient *client) {
+	delete(s.spectators, currentClient.ID)
+	currentClient.Cancel()
 }
 
 func (s *GameServer) removePlayer(playerID uuid.UUID) {
-	s.game.Mu.Lock()
-	defer s.game.Mu.Unlock()
+	game := s.currentGame()
+	game.Mu.Lock()
+	defer game.Mu.Unlock()
 
-	s.game.RemoveEntity(playerID)
+	game.RemoveEntity(playerID)
 
 	resp := proto.Response{
 		Action: &proto.Response_RemoveEntity{
@@ -185,7 +494,7 @@ func (s *GameServer) removePlayer(playerID uuid.UUID) {
 	s.broadcast(&resp)
 }
 
-func (s *GameServer) handleConnectRequest(req *proto.Request, srv proto.Game_StreamServer) (uuid.UUID, error) {
+func (s *GameServer) handleConnectRequest(req *proto.Request, srv proto.Game_StreamServer) (uuid.UUID, proto.Role, error) {
 	time.Sleep(time.Second * 1)
 
 	connect := req.GetConnect()
@@ -194,12 +503,16 @@ func (s *GameServer) handleConnectRequest(req *proto.Request, srv proto.Game_Str
 
 	playerID, err := uuid.Parse(connect.Id)
 	if err != nil {
-		return playerID, err
+		return playerID, connect.Role, err
 	}
 
 	re := regexp.MustCompile("^[a-zA-Z0-9]+$")
 	if !re.MatchString(connect.Name) {
-		return playerID, errors.New("invalid name provided")
+		return playerID, connect.Role, errors.New("invalid name provided")
+	}
+
+	if connect.Role == proto.Role_SPECTATOR {
+		return s.handleSpectatorConnect(connect, playerID, srv)
 	}
 
 	startCoordinate := backend.Coordinate{X: 0, Y: 0}
@@ -211,19 +524,56 @@ func (s *GameServer) handleConnectRequest(req *proto.Request, srv proto.Game_Str
 		CurrentPosition: startCoordinate,
 	}
 
-	s.game.Mu.Lock()
-	s.game.AddEntity(player)
-	s.game.Mu.Unlock()
+	game := s.currentGame()
+	game.Mu.Lock()
+	game.AddEntity(player)
+	game.Mu.Unlock()
+
+	if err := s.sendInitialize(srv); err != nil {
+		s.removePlayer(playerID)
+		return playerID, connect.Role, err
+	}
+
+	log.Printf("sent initialize message for %s", connect.Name)
 
-	s.game.Mu.RLock()
+	resp := proto.Response{
+		Action: &proto.Response_AddEntity{
+			AddEntity: &proto.AddEntity{
+				Entity: proto.GetProtoEntity(player),
+			},
+		},
+	}
+	s.broadcast(&resp)
+	s.broadcastLobbyEvent(proto.LobbyEvent_PLAYER_JOINED, playerID, connect.Name)
+	s.broadcastLobbyEvent(proto.LobbyEvent_PLAYER_READY, playerID, connect.Name)
+
+	return playerID, connect.Role, nil
+}
+
+// handleSpectatorConnect mirrors handleConnectRequest for a spectating
+// client: it receives the same Initialize snapshot and every subsequent
+// broadcast, but is never added to the game as an entity.
+func (s *GameServer) handleSpectatorConnect(connect *proto.Connect, playerID uuid.UUID, srv proto.Game_StreamServer) (uuid.UUID, proto.Role, error) {
+	if err := s.sendInitialize(srv); err != nil {
+		return playerID, connect.Role, err
+	}
+
+	log.Printf("sent initialize message for spectator %s", connect.Name)
+
+	return playerID, connect.Role, nil
+}
+
+func (s *GameServer) sendInitialize(srv proto.Game_StreamServer) error {
+	game := s.currentGame()
+	game.Mu.RLock()
 	entities := make([]*proto.Entity, 0)
-	for _, entity := range s.game.Entities {
+	for _, entity := range game.Entities {
 		protoEntity := proto.GetProtoEntity(entity)
 		if protoEntity != nil {
 			entities = append(entities, protoEntity)
 		}
 	}
-	s.game.Mu.RUnlock()
+	game.Mu.RUnlock()
 
 	resp := proto.Response{
 		Action: &proto.Response_Initialize{
@@ -233,32 +583,23 @@ func (s *GameServer) handleConnectRequest(req *proto.Request, srv proto.Game_Str
 		},
 	}
 
-	if err := srv.Send(&resp); err != nil {
-		s.removePlayer(playerID)
-		return playerID, err
-	}
-
-	log.Printf("sent initialize message for %s", connect.Name)
-
-	resp = proto.Response{
-		Action: &proto.Response_AddEntity{
-			AddEntity: &proto.AddEntity{
-				Entity: proto.GetProtoEntity(player),
-			},
-		},
-	}
-	s.broadcast(&resp)
-
-	return playerID, nil
+	return srv.Send(&resp)
 }
 
 func (s *GameServer) handleMoveRequest(req *proto.Request, currentClient *client) {
 	move := req.GetMove()
 
-	s.game.ActionChannel <- backend.MoveAction{
+	s.touchActivity(currentClient.ID)
+
+	sentAt, err := ptypes.Timestamp(move.SentAt)
+	if err != nil {
+		sentAt = time.Now()
+	}
+
+	s.bufferAction(currentClient.ID, move.Seq, sentAt, backend.MoveAction{
 		ID:        currentClient.ID,
 		Direction: proto.GetBackendDirection(move.Direction),
-	}
+	})
 }
 
 func (s *GameServer) handleLaserRequest(req *proto.Request, currentClient *client) {
@@ -268,16 +609,73 @@ func (s *GameServer) handleLaserRequest(req *proto.Request, currentClient *clien
 		return
 	}
 
-	s.game.ActionChannel <- backend.LaserAction{
+	s.touchActivity(currentClient.ID)
+
+	sentAt, err := ptypes.Timestamp(laser.SentAt)
+	if err != nil {
+		sentAt = time.Now()
+	}
+
+	s.bufferAction(currentClient.ID, laser.Seq, sentAt, backend.LaserAction{
 		OwnerID:   currentClient.ID,
 		ID:        id,
 		Direction: proto.GetBackendDirection(laser.Direction),
+	})
+}
+
+// handleChatRequest relays a chat message to every connected client. Chat
+// counts as activity for the idle watchdog the same as moving or firing.
+func (s *GameServer) handleChatRequest(req *proto.Request, currentClient *client) {
+	chat := req.GetChat()
+
+	s.touchActivity(currentClient.ID)
+
+	s.broadcast(&proto.Response{
+		Action: &proto.Response_Chat{
+			Chat: &proto.ChatMessage{
+				PlayerId:   currentClient.ID.String(),
+				PlayerName: currentClient.Name,
+				Message:    chat.Message,
+			},
+		},
+	})
+}
+
+// handlePingRequest estimates a client's one-way clock skew from its local
+// send time, and stores it as RTTOffset so bufferAction's deadline can
+// account for that client running ahead of or behind the server's clock.
+func (s *GameServer) handlePingRequest(req *proto.Request, currentClient *client) {
+	ping := req.GetPing()
+
+	clientTime, err := ptypes.Timestamp(ping.ClientTime)
+	if err != nil {
+		return
 	}
+	offset := time.Since(clientTime)
+
+	s.mu.Lock()
+	if c, ok := s.clients[currentClient.ID]; ok {
+		c.RTTOffset = offset
+	}
+	s.mu.Unlock()
 }
 
 func (s *GameServer) Stream(srv proto.Game_StreamServer) error {
 	log.Println("start server")
 
+	req, err := srv.Recv()
+	if err != nil {
+		log.Printf("receive error %v", err)
+		return err
+	}
+
+	return s.StreamFrom(req, srv)
+}
+
+// StreamFrom runs the Stream loop starting from an already-received first
+// request, allowing a LobbyServer to peek the connect message's MatchId
+// before handing the rest of the stream off to the matching GameServer.
+func (s *GameServer) StreamFrom(req *proto.Request, srv proto.Game_StreamServer) error {
 	ctx, cancel := context.WithCancel(srv.Context())
 
 	var currentClient *client
@@ -290,38 +688,77 @@ func (s *GameServer) Stream(srv proto.Game_StreamServer) error {
 		default:
 		}
 
-		req, err := srv.Recv()
-		if err != nil {
-			log.Printf("receive error %v", err)
-			if currentClient != nil {
-				s.mu.Lock()
-				s.removeClient(currentClient)
-				s.mu.Unlock()
-				s.removePlayer(currentClient.ID)
+		if req == nil {
+			var err error
+			req, err = srv.Recv()
+			if err != nil {
+				log.Printf("receive error %v", err)
+				if currentClient != nil {
+					s.mu.Lock()
+					if currentClient.Role == proto.Role_SPECTATOR {
+						s.removeSpectator(currentClient)
+					} else {
+						s.removeClient(currentClient)
+					}
+					s.mu.Unlock()
+					if currentClient.Role != proto.Role_SPECTATOR {
+						s.removePlayer(currentClient.ID)
+						s.broadcastLobbyEvent(proto.LobbyEvent_PLAYER_LEFT, currentClient.ID, currentClient.Name)
+					}
+				}
+				return err
 			}
-			return err
 		}
 
 		log.Printf("got message %+v", req)
 
 		if currentClient == nil && req.GetConnect() != nil {
-			playerID, err := s.handleConnectRequest(req, srv)
+			playerID, role, err := s.handleConnectRequest(req, srv)
 			if err != nil {
 				log.Printf("error when connecting %s: %+v", playerID.String(), err)
 				return err
 			}
 
-			s.mu.Lock()
 			currentClient = &client{
 				StreamServer: srv,
-				Cancel: cancel,
-				ID: playerID,
+				Cancel:       cancel,
+				ID:           playerID,
+				Name:         req.GetConnect().Name,
+				LastActivity: time.Now(),
+				Role:         role,
+			}
+
+			s.mu.Lock()
+			if role == proto.Role_SPECTATOR {
+				s.spectators[playerID] = currentClient
+				s.mu.Unlock()
+			} else {
+				// Enforce MaxPlayers at the same point (and under the same
+				// lock) that actually admits the client, since this is the
+				// real connection path: the lobby's JoinGame RPC is only
+				// advisory and a caller can dial Stream directly.
+				if s.maxPlayers > 0 && len(s.clients) >= s.maxPlayers {
+					s.mu.Unlock()
+					s.removePlayer(playerID)
+					log.Printf("rejecting connect from %s: match is full", playerID)
+					return errors.New("match is full")
+				}
+				s.clients[playerID] = currentClient
+				s.mu.Unlock()
 			}
-			s.clients[playerID] = currentClient
-			s.mu.Unlock()
 		}
 
 		if currentClient == nil {
+			req = nil
+			continue
+		}
+
+		if currentClient.Role == proto.Role_SPECTATOR {
+			switch req.GetAction().(type) {
+			case *proto.Request_Move, *proto.Request_Laser:
+				log.Printf("rejecting action from spectator %s", currentClient.ID)
+			}
+			req = nil
 			continue
 		}
 
@@ -330,6 +767,12 @@ func (s *GameServer) Stream(srv proto.Game_StreamServer) error {
 			s.handleMoveRequest(req, currentClient)
 		case *proto.Request_Laser:
 			s.handleLaserRequest(req, currentClient)
+		case *proto.Request_Chat:
+			s.handleChatRequest(req, currentClient)
+		case *proto.Request_Ping:
+			s.handlePingRequest(req, currentClient)
 		}
+
+		req = nil
 	}
 }